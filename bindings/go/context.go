@@ -0,0 +1,107 @@
+package quicksilver
+
+/*
+#include "../../bindings/c/quicksilver.h"
+#include <stdlib.h>
+*/
+import "C"
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"unsafe"
+)
+
+// Context is an isolated JS realm within a Runtime: its own global
+// object and module registry, but sharing the Runtime's heap and GC,
+// matching QuickJS's runtime/context split. Create one with
+// Runtime.NewContext when a single process needs several independent
+// globals without the overhead of a whole new Runtime.
+//
+// Like Runtime, Context operations are dispatched onto the owning
+// Runtime's dedicated OS thread, so a Context is safe to use from any
+// goroutine.
+type Context struct {
+	rt        *Runtime
+	ctx       *C.QsContext
+	closeOnce sync.Once
+}
+
+// NewContext creates a new isolated JS realm backed by r.
+func (r *Runtime) NewContext() (c *Context, err error) {
+	r.dispatch(func() {
+		raw := C.qs_context_new(r.rt)
+		if raw == nil {
+			err = fmt.Errorf("quicksilver: failed to create context")
+			return
+		}
+		c = &Context{rt: r, ctx: raw}
+		runtime.SetFinalizer(c, (*Context).Close)
+	})
+	return
+}
+
+// Close frees the context. It does not affect the owning Runtime or any
+// of its other Contexts. It is safe to call concurrently with, or more
+// than once alongside, any other Context method.
+func (c *Context) Close() {
+	c.closeOnce.Do(func() {
+		// c.ctx is read and nilled out inside the dispatched closure, not
+		// before it, so this can't race with a concurrent Eval/SetGlobal.
+		c.rt.dispatch(func() {
+			if c.ctx != nil {
+				C.qs_context_free(c.ctx)
+				c.ctx = nil
+			}
+		})
+	})
+}
+
+// Eval evaluates source within c's global scope.
+func (c *Context) Eval(source string) (result interface{}, err error) {
+	c.rt.dispatch(func() {
+		cs := C.CString(source)
+		defer C.free(unsafe.Pointer(cs))
+
+		var cerr C.QsError
+		val := C.qs_context_eval(c.ctx, cs, &cerr)
+		if val == nil {
+			err = errorFromC(&cerr)
+			return
+		}
+		defer C.qs_value_free(val)
+
+		result = valueToGo(val)
+	})
+	return
+}
+
+// SetGlobal sets a global variable within c's scope.
+func (c *Context) SetGlobal(name string, value interface{}) {
+	c.rt.dispatch(func() {
+		cn := C.CString(name)
+		defer C.free(unsafe.Pointer(cn))
+
+		val := goToValue(value)
+		defer C.qs_value_free(val)
+
+		C.qs_context_global_set(c.ctx, cn, val)
+	})
+}
+
+// GetGlobal gets a global variable from c's scope.
+func (c *Context) GetGlobal(name string) (result interface{}) {
+	c.rt.dispatch(func() {
+		cn := C.CString(name)
+		defer C.free(unsafe.Pointer(cn))
+
+		val := C.qs_context_global_get(c.ctx, cn)
+		if val == nil {
+			return
+		}
+		defer C.qs_value_free(val)
+
+		result = valueToGo(val)
+	})
+	return
+}
@@ -0,0 +1,65 @@
+package quicksilver
+
+/*
+#include "../../bindings/c/quicksilver.h"
+#include <stdlib.h>
+*/
+import "C"
+
+import "fmt"
+
+// Error represents a JavaScript exception that propagated out of the
+// runtime. Use errors.As to recover one from an error returned by Eval,
+// EvalValue, or Value.Call.
+type Error struct {
+	// Name is the JS error's constructor name, e.g. "TypeError".
+	Name string
+	// Message is the JS error's message property.
+	Message string
+	// Stack is the JS stack trace, if the engine captured one.
+	Stack string
+	// FileName and LineNumber locate where the error was thrown, when
+	// known.
+	FileName   string
+	LineNumber int
+	// Cause is not set by the errors this package returns: a Go error
+	// returned by a function registered with SetFunction is thrown into
+	// JS as its Error() text only, so the original Go error is not
+	// recoverable from the resulting *Error.
+	Cause error
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	if e.FileName != "" {
+		return fmt.Sprintf("quicksilver: %s: %s (%s:%d)", e.Name, e.Message, e.FileName, e.LineNumber)
+	}
+	return fmt.Sprintf("quicksilver: %s: %s", e.Name, e.Message)
+}
+
+// Unwrap returns the wrapped cause, if any, so errors.Is/As can see
+// through it.
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// errorFromC builds an *Error from a populated C.QsError, freeing the
+// underlying C strings.
+func errorFromC(cerr *C.QsError) *Error {
+	e := &Error{Name: "Error"}
+	if cerr.name != nil {
+		e.Name = C.GoString(cerr.name)
+	}
+	if cerr.message != nil {
+		e.Message = C.GoString(cerr.message)
+	}
+	if cerr.stack != nil {
+		e.Stack = C.GoString(cerr.stack)
+	}
+	if cerr.file_name != nil {
+		e.FileName = C.GoString(cerr.file_name)
+	}
+	e.LineNumber = int(cerr.line_number)
+	C.qs_error_free(cerr)
+	return e
+}
@@ -0,0 +1,32 @@
+package quicksilver
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestEvalThrownErrorCapturesDetails(t *testing.T) {
+	rt := New()
+	defer rt.Close()
+
+	_, err := rt.Eval(`function fail() { throw new TypeError("boom"); } fail();`)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	var jsErr *Error
+	if !errors.As(err, &jsErr) {
+		t.Fatalf("errors.As(%v, *Error): got false", err)
+	}
+
+	if jsErr.Name != "TypeError" {
+		t.Errorf("Name = %q, want %q", jsErr.Name, "TypeError")
+	}
+	if jsErr.Message != "boom" {
+		t.Errorf("Message = %q, want %q", jsErr.Message, "boom")
+	}
+	if !strings.Contains(jsErr.Stack, "fail") {
+		t.Errorf("Stack = %q, want it to mention %q", jsErr.Stack, "fail")
+	}
+}
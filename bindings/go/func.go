@@ -0,0 +1,223 @@
+package quicksilver
+
+/*
+#include "../../bindings/c/quicksilver.h"
+#include <stdlib.h>
+
+extern QsValue* goFuncTrampoline(void *ctx, QsValue **args, int argc);
+*/
+import "C"
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"unsafe"
+)
+
+var functionContextType = reflect.TypeOf(FunctionContext{})
+
+// registeredFunc is what funcRegistry stores for a function installed
+// with SetFunction: the reflected Go func value plus the Runtime it was
+// registered on, so the trampoline can hand the callback a
+// FunctionContext bound to the right runtime.
+type registeredFunc struct {
+	fn reflect.Value
+	rt *Runtime
+}
+
+// funcRegistry holds the Go functions that have been installed as JS
+// globals, keyed by the handle passed through the C trampoline.
+var (
+	funcRegistryMu   sync.Mutex
+	funcRegistry     = map[uintptr]registeredFunc{}
+	funcRegistryNext uintptr
+)
+
+func registerFunc(rt *Runtime, fn reflect.Value) uintptr {
+	funcRegistryMu.Lock()
+	defer funcRegistryMu.Unlock()
+	funcRegistryNext++
+	handle := funcRegistryNext
+	funcRegistry[handle] = registeredFunc{fn: fn, rt: rt}
+	return handle
+}
+
+func lookupFunc(handle uintptr) (registeredFunc, bool) {
+	funcRegistryMu.Lock()
+	defer funcRegistryMu.Unlock()
+	rf, ok := funcRegistry[handle]
+	return rf, ok
+}
+
+// FunctionContext is passed to a registered Go function when its first
+// parameter has type FunctionContext, giving the callback a way to call
+// back into JS while it is running. A registered function's trampoline
+// runs synchronously on the Runtime's owning thread (it is invoked by C
+// mid-evaluation), so FunctionContext's methods talk to the runtime
+// directly instead of going through Runtime.dispatch, which would
+// deadlock waiting for a thread that is blocked calling it.
+//
+// A FunctionContext must not be used after the registered function that
+// received it returns.
+type FunctionContext struct {
+	rt *Runtime
+}
+
+// Call invokes fn with args without dispatching onto another goroutine,
+// since FunctionContext is only ever handed to code already running on
+// the runtime's owning thread.
+func (fc FunctionContext) Call(fn Value, args ...interface{}) (Value, error) {
+	return callValueLocked(fc.rt, fn.val(), args)
+}
+
+// Eval evaluates source in the runtime's global scope without
+// dispatching, for the same reason as Call.
+func (fc FunctionContext) Eval(source string) (interface{}, error) {
+	return fc.rt.evalLocked(source)
+}
+
+// SetFunction installs fn as a global JavaScript function called name. fn
+// may be any Go func value; its arguments are populated by converting the
+// JS call arguments with goToValue's inverse, and its return value (and
+// trailing error, if any) is converted back with goToValue.
+//
+// If fn's first parameter is a FunctionContext, it receives that context
+// instead of consuming a JS argument; fn may use it to call back into JS
+// while it runs.
+//
+// fn may be variadic. Trailing JS arguments beyond fn's fixed parameters
+// are converted to the variadic slice's element type, exactly as if JS
+// had called fn(a, b, c) and Go had spread c... into it.
+//
+// If fn's final return value is an error, a non-nil error returned by fn
+// causes the JS call to throw an exception carrying the error's message
+// instead of returning a value.
+func (r *Runtime) SetFunction(name string, fn interface{}) error {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func {
+		return fmt.Errorf("quicksilver: SetFunction: %q is not a func", name)
+	}
+
+	handle := registerFunc(r, v)
+
+	var failed bool
+	r.dispatch(func() {
+		cn := C.CString(name)
+		defer C.free(unsafe.Pointer(cn))
+
+		failed = C.qs_register_func(r.rt, cn, C.uintptr_t(handle)) != 0
+	})
+	if failed {
+		return fmt.Errorf("quicksilver: failed to register function %q", name)
+	}
+	return nil
+}
+
+// RegisterFunc is an alias for SetFunction, matching the naming used by
+// other QuickJS Go bindings.
+func (r *Runtime) RegisterFunc(name string, fn interface{}) error {
+	return r.SetFunction(name, fn)
+}
+
+//export goFuncTrampoline
+func goFuncTrampoline(ctx unsafe.Pointer, args **C.QsValue, argc C.int) (result *C.QsValue) {
+	handle := uintptr(ctx)
+	rf, ok := lookupFunc(handle)
+	if !ok {
+		return nil
+	}
+	fn := rf.fn
+
+	// A panicking callback (a bad argument conversion, or the user's own
+	// code) must not escape this //export'ed function: that would crash
+	// the whole process instead of just failing the JS call that
+	// triggered it.
+	defer func() {
+		if p := recover(); p != nil {
+			result = throwError(fmt.Sprintf("panic in registered function: %v", p))
+		}
+	}()
+
+	n := int(argc)
+	var argv []*C.QsValue
+	if n > 0 {
+		argv = (*[1 << 16]*C.QsValue)(unsafe.Pointer(args))[:n:n]
+	}
+
+	ft := fn.Type()
+	numIn := ft.NumIn()
+	variadic := ft.IsVariadic()
+	fixedIn := numIn
+	if variadic {
+		fixedIn = numIn - 1
+	}
+
+	callArgs := make([]reflect.Value, 0, numIn)
+	if fixedIn > 0 && ft.In(0) == functionContextType {
+		callArgs = append(callArgs, reflect.ValueOf(FunctionContext{rt: rf.rt}))
+	}
+
+argLoop:
+	for i := 0; i < n; i++ {
+		paramIdx := len(callArgs)
+		var argType reflect.Type
+		switch {
+		case paramIdx < fixedIn:
+			argType = ft.In(paramIdx)
+		case variadic:
+			argType = ft.In(numIn - 1).Elem()
+		default:
+			// fn takes no more arguments (and isn't variadic); ignore
+			// whatever extra arguments JS passed, same as a JS function
+			// ignores extra call arguments.
+			break argLoop
+		}
+		callArgs = append(callArgs, convertArg(valueToGoDeep(argv[i]), argType))
+	}
+	for len(callArgs) < fixedIn {
+		callArgs = append(callArgs, reflect.Zero(ft.In(len(callArgs))))
+	}
+
+	results := fn.Call(callArgs)
+	if len(results) == 0 {
+		return C.qs_value_undefined()
+	}
+
+	last := results[len(results)-1]
+	if last.Type().Implements(reflect.TypeOf((*error)(nil)).Elem()) {
+		if !last.IsNil() {
+			err, _ := last.Interface().(error)
+			return throwError(err.Error())
+		}
+		results = results[:len(results)-1]
+	}
+	if len(results) == 0 {
+		return C.qs_value_undefined()
+	}
+	return goToValue(results[0].Interface())
+}
+
+// throwError raises msg as a JS exception. qs_throw_error copies msg
+// into the exception it constructs, so the C string is freed immediately
+// after the call instead of being leaked.
+func throwError(msg string) *C.QsValue {
+	cs := C.CString(msg)
+	defer C.free(unsafe.Pointer(cs))
+	return C.qs_throw_error(cs)
+}
+
+// convertArg converts a value produced by valueToGo into the concrete Go
+// type expected by a registered function's parameter.
+func convertArg(v interface{}, t reflect.Type) reflect.Value {
+	if v == nil {
+		return reflect.Zero(t)
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Type().AssignableTo(t) {
+		return rv
+	}
+	if rv.Type().ConvertibleTo(t) {
+		return rv.Convert(t)
+	}
+	return reflect.Zero(t)
+}
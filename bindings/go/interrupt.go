@@ -0,0 +1,109 @@
+package quicksilver
+
+/*
+#include "../../bindings/c/quicksilver.h"
+#include <stdlib.h>
+
+extern int goInterruptTrampoline(void *ctx);
+*/
+import "C"
+import (
+	gocontext "context"
+	"sync"
+	"unsafe"
+)
+
+var (
+	interruptRegistryMu   sync.Mutex
+	interruptRegistry     = map[uintptr]gocontext.Context{}
+	interruptRegistryNext uintptr
+)
+
+//export goInterruptTrampoline
+func goInterruptTrampoline(ctx unsafe.Pointer) C.int {
+	handle := uintptr(ctx)
+
+	interruptRegistryMu.Lock()
+	goCtx, ok := interruptRegistry[handle]
+	interruptRegistryMu.Unlock()
+	if !ok {
+		return 0
+	}
+
+	select {
+	case <-goCtx.Done():
+		return 1
+	default:
+		return 0
+	}
+}
+
+// EvalContext evaluates source like Eval, but aborts execution once ctx
+// is done. An aborted evaluation returns ctx.Err() itself (for example
+// context.Canceled), unwrapped; the underlying JS engine sees the abort as a
+// thrown exception and does not run to completion.
+//
+// Concurrent EvalContext calls on the same Runtime each get their own
+// interrupt registry token, so one call's ctx can't be mistaken for
+// another's once both are in flight on r's single owning thread.
+func (r *Runtime) EvalContext(ctx gocontext.Context, source string) (result interface{}, err error) {
+	interruptRegistryMu.Lock()
+	interruptRegistryNext++
+	token := interruptRegistryNext
+	interruptRegistry[token] = ctx
+	interruptRegistryMu.Unlock()
+	defer func() {
+		interruptRegistryMu.Lock()
+		delete(interruptRegistry, token)
+		interruptRegistryMu.Unlock()
+	}()
+
+	r.dispatch(func() {
+		C.qs_runtime_set_interrupt_handler(r.rt, C.uintptr_t(token))
+		defer C.qs_runtime_set_interrupt_handler(r.rt, 0)
+
+		cs := C.CString(source)
+		defer C.free(unsafe.Pointer(cs))
+
+		var cerr C.QsError
+		val := C.qs_eval(r.rt, cs, &cerr)
+		if val == nil {
+			if ctx.Err() != nil {
+				C.qs_error_free(&cerr)
+				err = ctx.Err()
+				return
+			}
+			err = errorFromC(&cerr)
+			return
+		}
+		defer C.qs_value_free(val)
+
+		result = valueToGo(val)
+	})
+	return
+}
+
+// SetMemoryLimit caps the total memory the runtime's JS heap may
+// allocate. Exceeding it causes subsequent allocations in JS to fail
+// with an out-of-memory exception. A limit of 0 removes the cap.
+func (r *Runtime) SetMemoryLimit(bytes uint64) {
+	r.dispatch(func() {
+		C.qs_runtime_set_memory_limit(r.rt, C.size_t(bytes))
+	})
+}
+
+// SetMaxStackSize caps the JS call stack size in bytes, guarding against
+// runaway recursion in untrusted scripts.
+func (r *Runtime) SetMaxStackSize(bytes uint64) {
+	r.dispatch(func() {
+		C.qs_runtime_set_max_stack_size(r.rt, C.size_t(bytes))
+	})
+}
+
+// SetGCThreshold sets the allocated-byte threshold at which the
+// runtime's garbage collector runs automatically.
+func (r *Runtime) SetGCThreshold(bytes uint64) {
+	r.dispatch(func() {
+		C.qs_runtime_set_gc_threshold(r.rt, C.size_t(bytes))
+	})
+}
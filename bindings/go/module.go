@@ -0,0 +1,123 @@
+package quicksilver
+
+/*
+#include "../../bindings/c/quicksilver.h"
+#include <stdlib.h>
+
+extern char* goModuleLoaderTrampoline(void *ctx, const char *name, const char *referrer);
+*/
+import "C"
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"unsafe"
+)
+
+// ModuleLoader resolves the source of an imported module by name.
+// referrer is the resolved path (or other identifier) of the module
+// doing the importing, or "" for the top-level module passed to
+// EvalModule/EvalFile; loaders that resolve relative paths need it to
+// support nested imports. ModuleLoader returns an error to make the
+// `import` statement that requested the module fail.
+type ModuleLoader func(name, referrer string) (source string, err error)
+
+var (
+	loaderRegistryMu sync.Mutex
+	loaderRegistry   = map[uintptr]ModuleLoader{}
+)
+
+// SetModuleLoader installs the callback used to resolve `import`
+// statements evaluated by EvalModule. Passing nil disables module
+// loading; unresolved imports will fail with an Error.
+func (r *Runtime) SetModuleLoader(loader ModuleLoader) {
+	loaderRegistryMu.Lock()
+	loaderRegistry[r.handle] = loader
+	loaderRegistryMu.Unlock()
+
+	r.dispatch(func() {
+		if loader == nil {
+			C.qs_runtime_set_module_loader(r.rt, 0)
+			return
+		}
+		C.qs_runtime_set_module_loader(r.rt, C.uintptr_t(r.handle))
+	})
+}
+
+// FileLoader returns a ModuleLoader that resolves module names as paths
+// relative to baseDir and reads their source from disk, so
+// `import { x } from "./foo.js"` works against a directory of plain
+// script files. A relative import inside a module loaded from a
+// subdirectory resolves against that module's directory, not baseDir, so
+// nested imports (e.g. "sub/foo.js" importing "./bar.js") find their
+// sibling files.
+func FileLoader(baseDir string) ModuleLoader {
+	return func(name, referrer string) (string, error) {
+		dir := baseDir
+		if referrer != "" {
+			dir = filepath.Dir(referrer)
+		}
+		path := name
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		return string(src), nil
+	}
+}
+
+//export goModuleLoaderTrampoline
+func goModuleLoaderTrampoline(ctx unsafe.Pointer, name *C.char, referrer *C.char) *C.char {
+	handle := uintptr(ctx)
+
+	loaderRegistryMu.Lock()
+	loader, ok := loaderRegistry[handle]
+	loaderRegistryMu.Unlock()
+	if !ok || loader == nil {
+		return nil
+	}
+
+	source, err := loader(C.GoString(name), C.GoString(referrer))
+	if err != nil {
+		return nil
+	}
+	return C.CString(source)
+}
+
+// EvalModule evaluates source as an ES module rather than a plain
+// script, so top-level `import`/`export` statements are permitted. Any
+// `import` encountered is resolved through the loader installed with
+// SetModuleLoader.
+func (r *Runtime) EvalModule(source, filename string) (result interface{}, err error) {
+	r.dispatch(func() {
+		cs := C.CString(source)
+		defer C.free(unsafe.Pointer(cs))
+		cf := C.CString(filename)
+		defer C.free(unsafe.Pointer(cf))
+
+		var cerr C.QsError
+		val := C.qs_eval_module(r.rt, cs, cf, &cerr)
+		if val == nil {
+			err = errorFromC(&cerr)
+			return
+		}
+		defer C.qs_value_free(val)
+
+		result = valueToGo(val)
+	})
+	return
+}
+
+// EvalFile reads the file at path and evaluates it as an ES module,
+// using path as the module's filename for error reporting and for
+// resolving relative imports.
+func (r *Runtime) EvalFile(path string) (interface{}, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return r.EvalModule(string(src), path)
+}
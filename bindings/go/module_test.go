@@ -0,0 +1,67 @@
+package quicksilver
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileLoaderResolvesRelativeImport(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "foo.js"), []byte(`export const x = 42;`), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	rt := New()
+	defer rt.Close()
+
+	rt.SetModuleLoader(FileLoader(dir))
+
+	result, err := rt.EvalModule(`import { x } from "./foo.js"; x;`, filepath.Join(dir, "main.js"))
+	if err != nil {
+		t.Fatalf("EvalModule: %v", err)
+	}
+	if result != float64(42) {
+		t.Fatalf("got %v, want 42", result)
+	}
+}
+
+func TestFileLoaderResolvesNestedImportAgainstReferrer(t *testing.T) {
+	dir := t.TempDir()
+	sub := filepath.Join(dir, "sub")
+	if err := os.Mkdir(sub, 0o755); err != nil {
+		t.Fatalf("Mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(sub, "bar.js"), []byte(`export const y = 7;`), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(sub, "foo.js"), []byte(`export { y } from "./bar.js";`), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	rt := New()
+	defer rt.Close()
+
+	rt.SetModuleLoader(FileLoader(dir))
+
+	result, err := rt.EvalModule(`import { y } from "./sub/foo.js"; y;`, filepath.Join(dir, "main.js"))
+	if err != nil {
+		t.Fatalf("EvalModule: %v", err)
+	}
+	if result != float64(7) {
+		t.Fatalf("got %v, want 7", result)
+	}
+}
+
+func TestFileLoaderMissingModule(t *testing.T) {
+	dir := t.TempDir()
+
+	rt := New()
+	defer rt.Close()
+
+	rt.SetModuleLoader(FileLoader(dir))
+
+	if _, err := rt.EvalModule(`import { x } from "./missing.js";`, filepath.Join(dir, "main.js")); err == nil {
+		t.Fatal("expected an error for an unresolved import")
+	}
+}
@@ -0,0 +1,146 @@
+package quicksilver
+
+/*
+#include "../../bindings/c/quicksilver.h"
+#include <stdlib.h>
+
+extern void goPromiseRejectionTrampoline(void *ctx, QsValue *reason, int handled);
+*/
+import "C"
+import (
+	"fmt"
+	"sync"
+	"time"
+	"unsafe"
+)
+
+// PromiseRejectionTracker is called whenever a JS promise is rejected.
+// handled reports whether a .catch (or equivalent) was attached before
+// the tracker fired; an unhandled rejection will typically still be
+// handled is false when the microtask queue settles.
+//
+// The tracker runs synchronously on the Runtime's owning thread, as part
+// of whatever call triggered the rejection. Because reason's accessor
+// methods dispatch back onto that same thread, calling them from inside
+// the tracker would deadlock; stash reason (e.g. with Interface() called
+// later, off-thread) rather than inspecting it inline.
+type PromiseRejectionTracker func(reason Value, handled bool)
+
+var (
+	rejectionTrackerMu sync.Mutex
+	rejectionTracker   = map[uintptr]PromiseRejectionTracker{}
+)
+
+// SetPromiseRejectionTracker installs a callback invoked for every
+// promise rejection in r, so unhandled rejections can be logged instead
+// of disappearing silently. Passing nil removes the tracker.
+func (r *Runtime) SetPromiseRejectionTracker(tracker PromiseRejectionTracker) {
+	rejectionTrackerMu.Lock()
+	rejectionTracker[r.handle] = tracker
+	rejectionTrackerMu.Unlock()
+
+	r.dispatch(func() {
+		if tracker == nil {
+			C.qs_runtime_set_rejection_tracker(r.rt, 0)
+			return
+		}
+		C.qs_runtime_set_rejection_tracker(r.rt, C.uintptr_t(r.handle))
+	})
+}
+
+//export goPromiseRejectionTrampoline
+func goPromiseRejectionTrampoline(ctx unsafe.Pointer, reason *C.QsValue, handled C.int) {
+	handle := uintptr(ctx)
+
+	rejectionTrackerMu.Lock()
+	tracker, ok := rejectionTracker[handle]
+	rejectionTrackerMu.Unlock()
+	if !ok || tracker == nil {
+		return
+	}
+
+	runtimeRegistryMu.Lock()
+	rt := runtimeRegistry[handle]
+	runtimeRegistryMu.Unlock()
+	if rt == nil {
+		return
+	}
+
+	tracker(newValue(rt, reason), handled != 0)
+}
+
+// runPendingJob executes a single queued microtask, if any. It must be
+// called from within a dispatch closure, since it touches r.rt directly.
+func runPendingJob(r *Runtime) (ran bool, err error) {
+	var cerr C.QsError
+	n := C.qs_execute_pending_job(r.rt, &cerr)
+	if n < 0 {
+		return false, errorFromC(&cerr)
+	}
+	return n > 0, nil
+}
+
+// RunPendingJobs drains the runtime's microtask queue, running queued
+// promise reaction callbacks until none remain. It returns the number of
+// jobs executed.
+func (r *Runtime) RunPendingJobs() (n int, err error) {
+	r.dispatch(func() {
+		for {
+			ran, jobErr := runPendingJob(r)
+			if jobErr != nil {
+				err = jobErr
+				return
+			}
+			if !ran {
+				return
+			}
+			n++
+		}
+	})
+	return
+}
+
+// AwaitPromise pumps the runtime's microtask queue until v settles,
+// returning its resolved value or, for a rejection, an error whose
+// message includes the rejection reason (converted as valueToGo does;
+// the error does not wrap it). While the queue is empty it briefly releases the
+// runtime's owning thread so an external goroutine can resolve v (e.g.
+// from a Go function registered with SetFunction); if v still hasn't
+// settled once timeout elapses, it returns a timeout error. A timeout of
+// 0 means wait indefinitely.
+func (r *Runtime) AwaitPromise(v Value, timeout time.Duration) (result Value, err error) {
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+
+	for {
+		var done bool
+		r.dispatch(func() {
+			switch C.qs_promise_state(v.val()) {
+			case C.QS_PROMISE_FULFILLED:
+				result = newValue(r, C.qs_promise_result(v.val()))
+				done = true
+				return
+			case C.QS_PROMISE_REJECTED:
+				reason := C.qs_promise_result(v.val())
+				defer C.qs_value_free(reason)
+				err = fmt.Errorf("quicksilver: promise rejected: %v", valueToGo(reason))
+				done = true
+				return
+			}
+
+			if _, jobErr := runPendingJob(r); jobErr != nil {
+				err = jobErr
+				done = true
+			}
+		})
+		if done {
+			return
+		}
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			return Value{}, fmt.Errorf("quicksilver: timed out waiting for promise to settle")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -24,14 +24,31 @@ import "C"
 import (
 	"fmt"
 	"runtime"
+	"sync"
 	"unsafe"
 )
 
 // Runtime represents a Quicksilver JavaScript runtime instance.
 type Runtime struct {
 	rt *C.QsRuntime
+	// handle identifies this Runtime to C callback trampolines (module
+	// loader, interrupt handler, promise rejection tracker) that can
+	// only carry an opaque integer across the cgo boundary.
+	handle uintptr
+	// ops serializes every operation on rt onto the single OS thread
+	// that owns it. See thread.go.
+	ops chan call
+	// closeOnce ensures Close's teardown runs exactly once, whether
+	// invoked explicitly or via the finalizer.
+	closeOnce sync.Once
 }
 
+var (
+	runtimeRegistryMu   sync.Mutex
+	runtimeRegistry     = map[uintptr]*Runtime{}
+	runtimeRegistryNext uintptr
+)
+
 // New creates a new Quicksilver runtime.
 func New() *Runtime {
 	rt := C.qs_runtime_new()
@@ -39,20 +56,54 @@ func New() *Runtime {
 		panic("quicksilver: failed to create runtime")
 	}
 	r := &Runtime{rt: rt}
+
+	runtimeRegistryMu.Lock()
+	runtimeRegistryNext++
+	r.handle = runtimeRegistryNext
+	runtimeRegistry[r.handle] = r
+	runtimeRegistryMu.Unlock()
+
+	r.startDispatcher()
+
 	runtime.SetFinalizer(r, (*Runtime).Close)
 	return r
 }
 
-// Close frees the runtime and all associated resources.
+// Close frees the runtime and all associated resources. It is safe to
+// call concurrently with, or more than once alongside, any other
+// Runtime method.
 func (r *Runtime) Close() {
-	if r.rt != nil {
-		C.qs_runtime_free(r.rt)
-		r.rt = nil
-	}
+	r.closeOnce.Do(func() {
+		// r.rt is read and nilled out inside the dispatched closure, not
+		// before it, so this can't race with an Eval/SetGlobal/etc. that
+		// a concurrent goroutine has already queued on r.ops.
+		r.dispatch(func() {
+			if r.rt != nil {
+				C.qs_runtime_free(r.rt)
+				r.rt = nil
+			}
+		})
+		r.stopDispatcher()
+
+		runtimeRegistryMu.Lock()
+		delete(runtimeRegistry, r.handle)
+		runtimeRegistryMu.Unlock()
+	})
 }
 
 // Eval evaluates JavaScript source code and returns the result.
-func (r *Runtime) Eval(source string) (interface{}, error) {
+//
+// Like every Runtime method, Eval runs on the OS thread that owns the
+// underlying QuickJS runtime, so it is safe to call concurrently from
+// multiple goroutines.
+func (r *Runtime) Eval(source string) (result interface{}, err error) {
+	r.dispatch(func() {
+		result, err = r.evalLocked(source)
+	})
+	return
+}
+
+func (r *Runtime) evalLocked(source string) (interface{}, error) {
 	cs := C.CString(source)
 	defer C.free(unsafe.Pointer(cs))
 
@@ -60,12 +111,7 @@ func (r *Runtime) Eval(source string) (interface{}, error) {
 	result := C.qs_eval(r.rt, cs, &cerr)
 
 	if result == nil {
-		msg := "evaluation failed"
-		if cerr.message != nil {
-			msg = C.GoString(cerr.message)
-			C.qs_error_free(&cerr)
-		}
-		return nil, fmt.Errorf("quicksilver: %s", msg)
+		return nil, errorFromC(&cerr)
 	}
 	defer C.qs_value_free(result)
 
@@ -74,27 +120,32 @@ func (r *Runtime) Eval(source string) (interface{}, error) {
 
 // SetGlobal sets a global variable in the runtime.
 func (r *Runtime) SetGlobal(name string, value interface{}) {
-	cn := C.CString(name)
-	defer C.free(unsafe.Pointer(cn))
+	r.dispatch(func() {
+		cn := C.CString(name)
+		defer C.free(unsafe.Pointer(cn))
 
-	val := goToValue(value)
-	defer C.qs_value_free(val)
+		val := goToValue(value)
+		defer C.qs_value_free(val)
 
-	C.qs_global_set(r.rt, cn, val)
+		C.qs_global_set(r.rt, cn, val)
+	})
 }
 
 // GetGlobal gets a global variable from the runtime.
-func (r *Runtime) GetGlobal(name string) interface{} {
-	cn := C.CString(name)
-	defer C.free(unsafe.Pointer(cn))
-
-	result := C.qs_global_get(r.rt, cn)
-	if result == nil {
-		return nil
-	}
-	defer C.qs_value_free(result)
+func (r *Runtime) GetGlobal(name string) (result interface{}) {
+	r.dispatch(func() {
+		cn := C.CString(name)
+		defer C.free(unsafe.Pointer(cn))
+
+		val := C.qs_global_get(r.rt, cn)
+		if val == nil {
+			return
+		}
+		defer C.qs_value_free(val)
 
-	return valueToGo(result)
+		result = valueToGo(val)
+	})
+	return
 }
 
 func valueToGo(val *C.QsValue) interface{} {
@@ -140,6 +191,24 @@ func goToValue(value interface{}) *C.QsValue {
 		cs := C.CString(v)
 		defer C.free(unsafe.Pointer(cs))
 		return C.qs_value_string(cs)
+	case map[string]interface{}:
+		obj := C.qs_value_object_new()
+		for key, elem := range v {
+			cn := C.CString(key)
+			ev := goToValue(elem)
+			C.qs_value_set_property(obj, cn, ev)
+			C.qs_value_free(ev)
+			C.free(unsafe.Pointer(cn))
+		}
+		return obj
+	case []interface{}:
+		arr := C.qs_value_array_new()
+		for _, elem := range v {
+			ev := goToValue(elem)
+			C.qs_value_array_push(arr, ev)
+			C.qs_value_free(ev)
+		}
+		return arr
 	default:
 		cs := C.CString(fmt.Sprintf("%v", v))
 		defer C.free(unsafe.Pointer(cs))
@@ -0,0 +1,39 @@
+package quicksilver
+
+import "runtime"
+
+// call is a unit of work dispatched onto a Runtime's owning goroutine.
+type call struct {
+	fn   func()
+	done chan struct{}
+}
+
+// startDispatcher launches the goroutine that owns r's underlying
+// QuickJS runtime for its entire lifetime. Embedded JS engines are not
+// safe to call from more than one OS thread, so every operation on r
+// (and on any Context created from it) is funneled through this single,
+// thread-locked goroutine rather than run on the caller's goroutine.
+func (r *Runtime) startDispatcher() {
+	r.ops = make(chan call)
+	go func() {
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+		for c := range r.ops {
+			c.fn()
+			close(c.done)
+		}
+	}()
+}
+
+// dispatch runs fn on r's dedicated goroutine and blocks until it
+// completes.
+func (r *Runtime) dispatch(fn func()) {
+	c := call{fn: fn, done: make(chan struct{})}
+	r.ops <- c
+	<-c.done
+}
+
+// stopDispatcher shuts down r's dedicated goroutine. Called from Close.
+func (r *Runtime) stopDispatcher() {
+	close(r.ops)
+}
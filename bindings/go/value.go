@@ -0,0 +1,245 @@
+package quicksilver
+
+/*
+#include "../../bindings/c/quicksilver.h"
+#include <stdlib.h>
+*/
+import "C"
+import (
+	"fmt"
+	"runtime"
+	"unsafe"
+)
+
+// Value wraps a live QuickJS value so callers can inspect objects and
+// arrays without immediately flattening them to Go primitives. A Value
+// must not outlive the Runtime it came from.
+//
+// Value itself is just a handle: it wraps a pointer to a heap-allocated
+// valueHandle so that copying a Value (which happens on every method
+// call and return, since Value is passed by value) never leaves the
+// original finalizer target unreferenced. The finalizer must be attached
+// to something only the last live copy stops pointing at, not to a
+// stack-local struct that each copy duplicates.
+type Value struct {
+	h *valueHandle
+}
+
+type valueHandle struct {
+	rt  *Runtime
+	val *C.QsValue
+}
+
+func newValue(rt *Runtime, val *C.QsValue) Value {
+	h := &valueHandle{rt: rt, val: val}
+	runtime.SetFinalizer(h, func(h *valueHandle) {
+		if h.val != nil {
+			C.qs_value_free(h.val)
+		}
+	})
+	return Value{h: h}
+}
+
+// IsObject reports whether v holds a JS object.
+func (v Value) IsObject() (ok bool) {
+	v.h.rt.dispatch(func() {
+		ok = C.qs_value_type(v.h.val) == C.QS_TYPE_OBJECT
+	})
+	return
+}
+
+// IsArray reports whether v holds a JS array.
+func (v Value) IsArray() (ok bool) {
+	v.h.rt.dispatch(func() {
+		ok = bool(C.qs_value_is_array(v.h.val))
+	})
+	return
+}
+
+// IsFunction reports whether v holds a callable JS function.
+func (v Value) IsFunction() (ok bool) {
+	v.h.rt.dispatch(func() {
+		ok = C.qs_value_type(v.h.val) == C.QS_TYPE_FUNCTION
+	})
+	return
+}
+
+// Get returns the named property of an object value.
+func (v Value) Get(name string) (result Value) {
+	v.h.rt.dispatch(func() {
+		cn := C.CString(name)
+		defer C.free(unsafe.Pointer(cn))
+
+		result = newValue(v.h.rt, C.qs_value_get_property(v.h.val, cn))
+	})
+	return
+}
+
+// Set assigns the named property of an object value.
+func (v Value) Set(name string, value interface{}) {
+	v.h.rt.dispatch(func() {
+		cn := C.CString(name)
+		defer C.free(unsafe.Pointer(cn))
+
+		val := goToValue(value)
+		defer C.qs_value_free(val)
+
+		C.qs_value_set_property(v.h.val, cn, val)
+	})
+}
+
+// Len returns the length of an array value, or 0 if v is not an array.
+func (v Value) Len() (n int) {
+	v.h.rt.dispatch(func() {
+		if C.qs_value_is_array(v.h.val) {
+			n = int(C.qs_value_array_len(v.h.val))
+		}
+	})
+	return
+}
+
+// Index returns the element at i of an array value.
+func (v Value) Index(i int) (result Value) {
+	v.h.rt.dispatch(func() {
+		result = newValue(v.h.rt, C.qs_value_array_get(v.h.val, C.int(i)))
+	})
+	return
+}
+
+// Call invokes v as a JS function with the given arguments, using the
+// global object as the receiver.
+func (v Value) Call(args ...interface{}) (result Value, err error) {
+	if !v.IsFunction() {
+		return Value{}, fmt.Errorf("quicksilver: value is not a function")
+	}
+
+	v.h.rt.dispatch(func() {
+		result, err = callValueLocked(v.h.rt, v.h.val, args)
+	})
+	return
+}
+
+// callValueLocked invokes the JS function val with args. It must be
+// called from within a dispatch closure (or, equivalently, from code
+// that is already known to be running on rt's owning thread, such as a
+// FunctionContext method).
+func callValueLocked(rt *Runtime, val *C.QsValue, args []interface{}) (Value, error) {
+	cargs := make([]*C.QsValue, len(args))
+	for i, a := range args {
+		cargs[i] = goToValue(a)
+	}
+	defer func() {
+		for _, a := range cargs {
+			C.qs_value_free(a)
+		}
+	}()
+
+	var argsPtr **C.QsValue
+	if len(cargs) > 0 {
+		argsPtr = &cargs[0]
+	}
+
+	var cerr C.QsError
+	result := C.qs_value_call(rt.rt, val, argsPtr, C.int(len(cargs)), &cerr)
+	if result == nil {
+		return Value{}, errorFromC(&cerr)
+	}
+	return newValue(rt, result), nil
+}
+
+// Interface recursively converts v into plain Go values: objects become
+// map[string]interface{}, arrays become []interface{}, and primitives
+// convert as valueToGo does.
+func (v Value) Interface() interface{} {
+	switch {
+	case v.IsArray():
+		n := v.Len()
+		out := make([]interface{}, n)
+		for i := 0; i < n; i++ {
+			out[i] = v.Index(i).Interface()
+		}
+		return out
+	case v.IsObject():
+		out := map[string]interface{}{}
+		var names []string
+		v.h.rt.dispatch(func() {
+			keys := C.qs_value_own_keys(v.h.val)
+			defer C.qs_string_list_free(keys)
+			n := int(keys.len)
+			items := (*[1 << 16]*C.char)(unsafe.Pointer(keys.items))[:n:n]
+			for _, cn := range items {
+				names = append(names, C.GoString(cn))
+			}
+		})
+		for _, name := range names {
+			out[name] = v.Get(name).Interface()
+		}
+		return out
+	default:
+		var result interface{}
+		v.h.rt.dispatch(func() {
+			result = valueToGo(v.h.val)
+		})
+		return result
+	}
+}
+
+// valueToGoDeep recursively converts val into plain Go values, exactly
+// as Value.Interface does, but by calling the C API directly instead of
+// going through a Value and its dispatching methods. It must only be
+// called from code already running on val's owning thread, such as
+// goFuncTrampoline, which runs synchronously during qs_eval.
+func valueToGoDeep(val *C.QsValue) interface{} {
+	switch {
+	case bool(C.qs_value_is_array(val)):
+		n := int(C.qs_value_array_len(val))
+		out := make([]interface{}, n)
+		for i := 0; i < n; i++ {
+			elem := C.qs_value_array_get(val, C.int(i))
+			out[i] = valueToGoDeep(elem)
+			C.qs_value_free(elem)
+		}
+		return out
+	case C.qs_value_type(val) == C.QS_TYPE_OBJECT:
+		out := map[string]interface{}{}
+		keys := C.qs_value_own_keys(val)
+		defer C.qs_string_list_free(keys)
+		n := int(keys.len)
+		items := (*[1 << 16]*C.char)(unsafe.Pointer(keys.items))[:n:n]
+		for _, cn := range items {
+			name := C.GoString(cn)
+			prop := C.qs_value_get_property(val, cn)
+			out[name] = valueToGoDeep(prop)
+			C.qs_value_free(prop)
+		}
+		return out
+	default:
+		return valueToGo(val)
+	}
+}
+
+// EvalValue evaluates source and returns the result as a Value, without
+// flattening objects and arrays to Go primitives.
+func (r *Runtime) EvalValue(source string) (result Value, err error) {
+	r.dispatch(func() {
+		cs := C.CString(source)
+		defer C.free(unsafe.Pointer(cs))
+
+		var cerr C.QsError
+		val := C.qs_eval(r.rt, cs, &cerr)
+		if val == nil {
+			err = errorFromC(&cerr)
+			return
+		}
+		result = newValue(r, val)
+	})
+	return
+}
+
+// val returns the underlying C value pointer, for use by other files in
+// this package that need to pass a Value across the cgo boundary (e.g.
+// promise.go's promise-state polling) without going through a Value
+// method and its own dispatch.
+func (v Value) val() *C.QsValue {
+	return v.h.val
+}